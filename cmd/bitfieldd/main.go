@@ -0,0 +1,267 @@
+// Command bitfieldd serves the Redis BITFIELD and BITFIELD_RO commands
+// over a TCP socket using the RESP2/RESP3 wire protocol, on top of the
+// pkg/bitfield library.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/CodeLingoBot/go-cookbook/pkg/bitfield"
+)
+
+var (
+	addr    = flag.String("addr", ":6380", "address to listen on")
+	oneshot = flag.Bool("oneshot", false, "run a single BITFIELD command against an empty key and exit, instead of serving")
+)
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+
+	if *oneshot {
+		runOneshot(flag.Args())
+		return
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("bitfieldd: %v", err)
+	}
+	log.Printf("bitfieldd: listening on %s", *addr)
+
+	s := newStore()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			log.Printf("bitfieldd: accept: %v", err)
+			continue
+		}
+		go serve(conn, s)
+	}
+}
+
+// runOneshot preserves the old redis-bitfield CLI behavior: parse and run
+// one BITFIELD command against a fresh, empty key, then print the results
+// and exit, instead of starting the server.
+func runOneshot(args []string) {
+	if len(args) < 1 {
+		fmt.Println("usage: bitfieldd -oneshot <key> [GET|SET|INCRBY|OVERFLOW ...]")
+		return
+	}
+
+	ops, err := bitfield.Parse(args[1:])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var data bitfield.Bitmap
+	for i, op := range ops {
+		r, ok := bitfield.Apply(&data, op)
+		if !ok {
+			fmt.Printf("%d: (nil)\n", i)
+			continue
+		}
+		fmt.Printf("%d: %d\n", i, r)
+	}
+}
+
+// store is the server's keyspace. Each key has its own mutex, so BITFIELD
+// calls against different keys never block each other; a coarser RWMutex
+// only guards creating new entries in the map itself.
+type store struct {
+	mu   sync.RWMutex
+	keys map[string]*entry
+}
+
+type entry struct {
+	mu   sync.Mutex
+	data bitfield.Bitmap
+}
+
+func newStore() *store {
+	return &store{keys: make(map[string]*entry)}
+}
+
+func (s *store) entry(key string) *entry {
+	s.mu.RLock()
+	e, ok := s.keys[key]
+	s.mu.RUnlock()
+	if ok {
+		return e
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.keys[key]; ok {
+		return e
+	}
+	e = &entry{}
+	s.keys[key] = e
+	return e
+}
+
+func serve(conn net.Conn, s *store) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	for {
+		argv, err := readCommand(r)
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("bitfieldd: %s: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+		if len(argv) == 0 {
+			continue
+		}
+
+		handle(argv, s, w)
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func handle(argv []string, s *store, w *bufio.Writer) {
+	cmd := strings.ToUpper(argv[0])
+	if cmd != "BITFIELD" && cmd != "BITFIELD_RO" {
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", argv[0]))
+		return
+	}
+	if len(argv) < 2 {
+		writeError(w, "ERR wrong number of arguments for 'bitfield' command")
+		return
+	}
+
+	ops, err := bitfield.Parse(argv[2:])
+	if err != nil {
+		writeError(w, redisErrorText(err))
+		return
+	}
+
+	if cmd == "BITFIELD_RO" {
+		for _, op := range ops {
+			if op.Kind != bitfield.OpGet {
+				writeError(w, "ERR BITFIELD_RO only supports the GET subcommand")
+				return
+			}
+		}
+	}
+
+	e := s.entry(argv[1])
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	results := make([]*int64, len(ops))
+	for i, op := range ops {
+		r, ok := bitfield.Apply(&e.data, op)
+		if ok {
+			results[i] = &r
+		}
+	}
+	writeIntArray(w, results)
+}
+
+// readCommand reads one request from the client: either a plain
+// space-separated command line, or a RESP array of bulk strings (what
+// redis-cli and real Redis clients actually send).
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, fmt.Errorf("bad array header %q", line)
+	}
+	if n < 0 {
+		// *-1\r\n is RESP's null array; there are no elements to read.
+		return nil, nil
+	}
+
+	argv := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		head, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		head = strings.TrimRight(head, "\r\n")
+		if len(head) == 0 || head[0] != '$' {
+			return nil, fmt.Errorf("expected bulk string header, got %q", head)
+		}
+		size, err := strconv.Atoi(head[1:])
+		if err != nil {
+			return nil, fmt.Errorf("bad bulk string header %q", head)
+		}
+		if size < 0 {
+			// $-1\r\n is RESP's null bulk string; treat it as empty.
+			argv = append(argv, "")
+			continue
+		}
+
+		buf := make([]byte, size+2) // +2 for the trailing CRLF
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		argv = append(argv, string(buf[:size]))
+	}
+	return argv, nil
+}
+
+// writeIntArray writes a RESP array of integer replies. A nil entry (an Op
+// that Apply reported as not ok, such as an INCRBY suppressed by OVERFLOW
+// FAIL) is written as a null bulk
+// string, exactly as real Redis does, instead of dropping it from the
+// array or coercing it to 0.
+func writeIntArray(w *bufio.Writer, values []*int64) {
+	fmt.Fprintf(w, "*%d\r\n", len(values))
+	for _, v := range values {
+		if v == nil {
+			fmt.Fprint(w, "$-1\r\n")
+			continue
+		}
+		fmt.Fprintf(w, ":%d\r\n", *v)
+	}
+}
+
+func writeError(w *bufio.Writer, msg string) {
+	fmt.Fprintf(w, "-%s\r\n", msg)
+}
+
+// redisErrorText turns one of pkg/bitfield's sentinel errors into the
+// "ERR ..." text real Redis sends over the wire for the same mistake;
+// pkg/bitfield itself only exposes sentinels so callers that don't speak
+// RESP aren't stuck with Redis-specific wording baked into err.Error().
+func redisErrorText(err error) string {
+	switch {
+	case errors.Is(err, bitfield.ErrSyntax):
+		return "ERR syntax error"
+	case errors.Is(err, bitfield.ErrBadOffset):
+		return "ERR bit offset is not an integer or out of range"
+	case errors.Is(err, bitfield.ErrBadValue):
+		return "ERR value is not an integer or out of range"
+	case errors.Is(err, bitfield.ErrBadOverflowType):
+		return "ERR Invalid OVERFLOW type specified"
+	default:
+		return "ERR " + strings.TrimPrefix(err.Error(), "bitfield: ")
+	}
+}
@@ -0,0 +1,253 @@
+// Command bitfieldgen generates typed Get<Field>/Set<Field> accessors for a
+// struct whose fields carry `bitfield:"name,bits"` tags, so hot paths can
+// read and write the packed wire form without going through
+// bitfield.Pack/Unpack's reflection.
+//
+// Usage (typically via a go:generate directive, as with stringer):
+//
+//	//go:generate go run github.com/CodeLingoBot/go-cookbook/cmd/bitfieldgen -type=Header
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	typeName = flag.String("type", "", "name of the struct type to generate accessors for (required)")
+	output   = flag.String("output", "", "output file name; default <type>_bitfield.go")
+)
+
+type field struct {
+	name   string
+	goType string
+	bits   uint
+	signed bool
+	offset uint
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("bitfieldgen: ")
+	flag.Parse()
+
+	if *typeName == "" {
+		log.Fatal("-type is required")
+	}
+
+	dir := "."
+	if args := flag.Args(); len(args) > 0 {
+		dir = args[0]
+	}
+
+	fields, pkgName, err := parseStruct(dir, *typeName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := generate(pkgName, *typeName, fields)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out := *output
+	if out == "" {
+		out = strings.ToLower(*typeName) + "_bitfield.go"
+	}
+	if err := os.WriteFile(filepath.Join(dir, out), src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseStruct finds the struct type named name among the .go files in dir
+// and returns its bitfield-tagged fields in declaration order, laid out
+// MSB-first starting at bit 0 (the same default Pack/Unpack use).
+func parseStruct(dir, name string) ([]field, string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for pkgName, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			fields, found, err := fieldsForType(file, name)
+			if err != nil {
+				return nil, "", err
+			}
+			if found {
+				return fields, pkgName, nil
+			}
+		}
+	}
+	return nil, "", fmt.Errorf("type %s not found in %s", name, dir)
+}
+
+func fieldsForType(file *ast.File, name string) ([]field, bool, error) {
+	var fields []field
+	var found bool
+	var outerErr error
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		if outerErr != nil {
+			return false
+		}
+		ts, ok := n.(*ast.TypeSpec)
+		if !ok || ts.Name.Name != name {
+			return true
+		}
+		st, ok := ts.Type.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		found = true
+
+		var offset uint
+		for _, f := range st.Fields.List {
+			if f.Tag == nil || len(f.Names) == 0 {
+				continue
+			}
+			tagValue, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				outerErr = fmt.Errorf("field %s: invalid struct tag: %w", f.Names[0].Name, err)
+				return false
+			}
+			spec, ok := reflect.StructTag(tagValue).Lookup("bitfield")
+			if !ok {
+				continue
+			}
+
+			goType := typeString(f.Type)
+			fname, bits, err := parseTag(spec, f.Names[0].Name)
+			if err != nil {
+				outerErr = err
+				return false
+			}
+			if err := validateWidth(goType, bits); err != nil {
+				outerErr = fmt.Errorf("field %s: %w", f.Names[0].Name, err)
+				return false
+			}
+
+			fields = append(fields, field{
+				name:   fname,
+				goType: goType,
+				bits:   bits,
+				signed: strings.HasPrefix(goType, "int"),
+				offset: offset,
+			})
+			offset += bits
+		}
+		return false
+	})
+
+	if outerErr != nil {
+		return nil, false, outerErr
+	}
+	return fields, found, nil
+}
+
+func typeString(expr ast.Expr) string {
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return fmt.Sprintf("%v", expr)
+}
+
+func parseTag(tag, fieldName string) (string, uint, error) {
+	parts := strings.SplitN(tag, ",", 2)
+	name := fieldName
+	if parts[0] != "" {
+		name = parts[0]
+	}
+	if len(parts) < 2 || parts[1] == "" {
+		return "", 0, fmt.Errorf("tag %q on %s is missing a bit width", tag, fieldName)
+	}
+	bits, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return "", 0, err
+	}
+	return name, uint(bits), nil
+}
+
+var maxBitsFor = map[string]uint{
+	"bool":   1,
+	"uint8":  8,
+	"int8":   8,
+	"uint16": 16,
+	"int16":  16,
+	"uint32": 32,
+	"int32":  32,
+	"uint64": 63,
+	"int64":  64,
+	"uint":   63,
+	"int":    64,
+}
+
+func validateWidth(goType string, bits uint) error {
+	max, ok := maxBitsFor[goType]
+	if !ok {
+		return fmt.Errorf("unsupported field type %q", goType)
+	}
+	if bits == 0 || bits > max {
+		return fmt.Errorf("%d bits does not fit in %s (max %d)", bits, goType, max)
+	}
+	return nil
+}
+
+func generate(pkgName, typeName string, fields []field) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "// Code generated by bitfieldgen -type=%s. DO NOT EDIT.\n\n", typeName)
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+	fmt.Fprintf(&buf, "import \"github.com/CodeLingoBot/go-cookbook/pkg/bitfield\"\n\n")
+	fmt.Fprintf(&buf, "// %sBits is the packed, reflection-free wire form of %s.\n", typeName, typeName)
+	fmt.Fprintf(&buf, "type %sBits bitfield.Bitmap\n\n", typeName)
+
+	for _, f := range fields {
+		getter, setter, cast := "GetU", "SetU", "uint64"
+		if f.signed {
+			getter, setter, cast = "GetI", "SetI", "int64"
+		}
+		getExpr := fmt.Sprintf("%s(bitfield.Bitmap(b).%s(%d, %d))", f.goType, getter, f.offset, f.bits)
+		setArg := cast + "(v)"
+		if f.goType == "bool" {
+			getExpr = fmt.Sprintf("bitfield.Bitmap(b).GetU(%d, %d) != 0", f.offset, f.bits)
+			setArg = "u"
+		}
+
+		fmt.Fprintf(&buf, "func (b %sBits) Get%s() %s {\n", typeName, exportName(f.name), f.goType)
+		fmt.Fprintf(&buf, "\treturn %s\n", getExpr)
+		fmt.Fprintf(&buf, "}\n\n")
+
+		fmt.Fprintf(&buf, "func (b *%sBits) Set%s(v %s) {\n", typeName, exportName(f.name), f.goType)
+		if f.goType == "bool" {
+			fmt.Fprintf(&buf, "\tvar u uint64\n\tif v {\n\t\tu = 1\n\t}\n")
+		}
+		fmt.Fprintf(&buf, "\tbm := bitfield.Bitmap(*b)\n")
+		fmt.Fprintf(&buf, "\tbm.%s(%d, %d, %s)\n", setter, f.offset, f.bits, setArg)
+		fmt.Fprintf(&buf, "\t*b = %sBits(bm)\n", typeName)
+		fmt.Fprintf(&buf, "}\n\n")
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+func exportName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
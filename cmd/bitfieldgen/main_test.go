@@ -0,0 +1,108 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func parseFields(t *testing.T, src, typeName string) ([]field, bool, error) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, 0)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	return fieldsForType(file, typeName)
+}
+
+const validSrc = `package p
+
+type Header struct {
+	Version uint8  ` + "`bitfield:\"version,4\"`" + `
+	Flags   uint8  ` + "`bitfield:\"flags,4\"`" + `
+}
+`
+
+func TestFieldsForTypeValid(t *testing.T) {
+	fields, found, err := parseFields(t, validSrc, "Header")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !found {
+		t.Fatalf("type not found")
+	}
+	if len(fields) != 2 {
+		t.Fatalf("got %d fields, want 2", len(fields))
+	}
+	if fields[0].name != "version" || fields[0].bits != 4 || fields[0].offset != 0 {
+		t.Fatalf("got %+v", fields[0])
+	}
+	if fields[1].name != "flags" || fields[1].bits != 4 || fields[1].offset != 4 {
+		t.Fatalf("got %+v", fields[1])
+	}
+}
+
+const badWidthSrc = `package p
+
+type Header struct {
+	Big uint8 ` + "`bitfield:\"big,12\"`" + `
+}
+`
+
+func TestFieldsForTypeReturnsErrorOnBadWidth(t *testing.T) {
+	_, _, err := parseFields(t, badWidthSrc, "Header")
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "12 bits does not fit in uint8") {
+		t.Fatalf("got err %v, want it to mention the bad width", err)
+	}
+}
+
+const missingWidthSrc = `package p
+
+type Header struct {
+	X uint8 ` + "`bitfield:\"x\"`" + `
+}
+`
+
+func TestFieldsForTypeReturnsErrorOnMissingWidth(t *testing.T) {
+	if _, _, err := parseFields(t, missingWidthSrc, "Header"); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+}
+
+func TestFieldsForTypeNotFound(t *testing.T) {
+	_, found, err := parseFields(t, validSrc, "Nope")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found {
+		t.Fatalf("got found=true for a type that isn't in the source")
+	}
+}
+
+func TestValidateWidth(t *testing.T) {
+	if err := validateWidth("uint8", 8); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := validateWidth("uint8", 9); err == nil {
+		t.Fatalf("expected error for 9 bits in a uint8")
+	}
+	if err := validateWidth("string", 4); err == nil {
+		t.Fatalf("expected error for unsupported type")
+	}
+}
+
+func TestGenerateProducesValidGo(t *testing.T) {
+	fields := []field{{name: "version", goType: "uint8", bits: 4, offset: 0}}
+	src, err := generate("p", "Header", fields)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "header_bitfield.go", src, 0); err != nil {
+		t.Fatalf("generated source does not parse: %v\n%s", err, src)
+	}
+}
@@ -0,0 +1,30 @@
+package bitfield
+
+import "testing"
+
+func BenchmarkGetU8Aligned(b *testing.B) {
+	data := Bitmap{0x12, 0x34, 0x56, 0x78}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = data.GetU(8, 8)
+	}
+}
+
+func BenchmarkGetU63Unaligned(b *testing.B) {
+	data := make(Bitmap, 16)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = data.GetU(3, 63)
+	}
+}
+
+func BenchmarkIncrByWrap(b *testing.B) {
+	data := make(Bitmap, 16)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = data.IncrBy(5, 37, 1, false, Wrap)
+	}
+}
@@ -0,0 +1,179 @@
+// Package bitfield implements the bit-level get/set/incrby primitives that
+// back Redis's BITFIELD command, operating directly on a byte slice instead
+// of a parsed command line.
+package bitfield
+
+import (
+	"errors"
+
+	"github.com/CodeLingoBot/go-cookbook/pkg/satmath"
+)
+
+// Bitmap is a byte slice addressed as a dense sequence of bits, MSB-first
+// within each byte, matching the layout Redis uses for BITFIELD and the
+// plain bit commands (SETBIT/GETBIT).
+type Bitmap []byte
+
+// Overflow selects how IncrBy behaves when an increment would push a value
+// outside the range representable by its declared bit width.
+type Overflow int
+
+const (
+	// Wrap truncates the result modulo 2^bits (the default).
+	Wrap Overflow = iota
+	// Sat clamps the result to the nearest representable value.
+	Sat
+	// Fail leaves the bitmap untouched and reports ErrOverflowFail.
+	Fail
+)
+
+var (
+	// ErrOverflowFail is returned by IncrBy when overflow is Fail and the
+	// increment would overflow.
+	ErrOverflowFail = errors.New("bitfield: value overflows and OVERFLOW FAIL is set")
+	// ErrInvalidType is returned when a bit width is out of range for the
+	// requested signedness (unsigned fields support up to 63 bits, signed
+	// fields up to 64).
+	ErrInvalidType = errors.New("bitfield: invalid type, bits out of range")
+	// ErrSyntax is returned by Parse when the subcommand tokens don't match
+	// any recognized GET/SET/INCRBY/OVERFLOW shape.
+	ErrSyntax = errors.New("bitfield: syntax error")
+	// ErrBadOffset is returned by ParseOffset when its argument isn't a
+	// valid plain or type-relative ("#n") bit offset.
+	ErrBadOffset = errors.New("bitfield: bit offset is not an integer or out of range")
+	// ErrBadValue is returned by ParseValue when its argument isn't a valid
+	// integer.
+	ErrBadValue = errors.New("bitfield: value is not an integer or out of range")
+	// ErrBadOverflowType is returned by ParseOverflow when its argument
+	// isn't WRAP, SAT, or FAIL.
+	ErrBadOverflowType = errors.New("bitfield: invalid OVERFLOW type specified")
+	// ErrBadRange is returned by parseRanges when a "start..end" range in a
+	// type's "@" suffix is malformed.
+	ErrBadRange = errors.New("bitfield: invalid bitfield range")
+)
+
+// GetU reads an unsigned value of the given bit width starting at offset.
+// Bits beyond the end of the bitmap read as zero, matching Redis semantics.
+func (b Bitmap) GetU(offset, bits uint) uint64 {
+	return getUnsignedBitfield(b, uint64(offset), uint64(bits))
+}
+
+// GetI reads a two's-complement signed value of the given bit width
+// starting at offset.
+func (b Bitmap) GetI(offset, bits uint) int64 {
+	unsigned := getUnsignedBitfield(b, uint64(offset), uint64(bits))
+	return signExtend(unsigned, uint64(bits))
+}
+
+// SetU writes an unsigned value at offset, growing the bitmap with zero
+// bytes if necessary, and returns the value that was previously stored
+// there.
+func (b *Bitmap) SetU(offset, bits uint, value uint64) uint64 {
+	old := getUnsignedBitfield(*b, uint64(offset), uint64(bits))
+	*b = setUnsignedBitfield(*b, uint64(offset), uint64(bits), value)
+	return old
+}
+
+// SetI writes a signed value at offset, growing the bitmap with zero bytes
+// if necessary, and returns the value that was previously stored there.
+func (b *Bitmap) SetI(offset, bits uint, value int64) int64 {
+	old := getUnsignedBitfield(*b, uint64(offset), uint64(bits))
+	*b = setUnsignedBitfield(*b, uint64(offset), uint64(bits), uint64(value))
+	return signExtend(old, uint64(bits))
+}
+
+// IncrBy adds incr to the value stored at offset, applying the requested
+// overflow semantics, and returns the resulting value. When ov is Fail and
+// the increment would overflow, the bitmap is left unchanged and
+// ErrOverflowFail is returned.
+func (b *Bitmap) IncrBy(offset, bits uint, incr int64, signed bool, ov Overflow) (int64, error) {
+	if signed {
+		old := b.GetI(offset, bits)
+		newValue, overflowed := checkSignedBitfieldOverflow(old, incr, uint64(bits), ov)
+		if overflowed && ov == Fail {
+			return 0, ErrOverflowFail
+		}
+		*b = setUnsignedBitfield(*b, uint64(offset), uint64(bits), uint64(newValue))
+		return newValue, nil
+	}
+
+	old := getUnsignedBitfield(*b, uint64(offset), uint64(bits))
+	newValue, overflowed := checkUnsignedBitfieldOverflow(old, incr, uint64(bits), ov)
+	if overflowed && ov == Fail {
+		return 0, ErrOverflowFail
+	}
+	*b = setUnsignedBitfield(*b, uint64(offset), uint64(bits), newValue)
+	return int64(newValue), nil
+}
+
+// signExtend sign-extends the low bits bits of value: shift the sign bit up
+// to bit 63, then use Go's arithmetic right shift on the signed view to
+// flood it back down.
+func signExtend(value, bits uint64) int64 {
+	shift := 64 - bits
+	return int64(value<<shift) >> shift
+}
+
+func setUnsignedBitfield(data Bitmap, offset, bits, value uint64) Bitmap {
+	if bits < 64 {
+		value &= 0xFFFFFFFFFFFFFFFF >> (64 - bits)
+	}
+	data = growBitmap(data, offset, bits)
+
+	if offset%8 == 0 && bits%8 == 0 {
+		return setAligned(data, offset>>3, bits, value)
+	}
+	return setUnaligned(data, offset, bits, value)
+}
+
+func getUnsignedBitfield(data Bitmap, offset, bits uint64) uint64 {
+	if bits == 0 {
+		return 0
+	}
+	if offset%8 == 0 && bits%8 == 0 {
+		return getAligned(data, offset>>3, bits)
+	}
+	return getUnaligned(data, offset, bits)
+}
+
+// growBitmap extends data with zero bytes so that offset+bits fits, the
+// same expansion behavior BITFIELD SET/INCRBY use.
+func growBitmap(data Bitmap, offset, bits uint64) Bitmap {
+	if offset+bits <= uint64(len(data)*8) {
+		return data
+	}
+	numExpandBits := offset + bits - uint64(len(data)*8)
+	numExpandBytes := numExpandBits / 8
+	if numExpandBits%8 > 0 {
+		numExpandBytes++
+	}
+	for i := uint64(0); i < numExpandBytes; i++ {
+		data = append(data, 0x00)
+	}
+	return data
+}
+
+// checkUnsignedBitfieldOverflow and checkSignedBitfieldOverflow apply
+// BITFIELD's OVERFLOW semantics on top of pkg/satmath's generic N-bit
+// add: WRAP and FAIL share the same truncated result (FAIL just turns the
+// overflow bool into an error one level up, in IncrBy), SAT clamps.
+func checkUnsignedBitfieldOverflow(value uint64, incr int64, bits uint64, ov Overflow) (uint64, bool) {
+	delta := uint64(incr)
+	if ov == Sat {
+		result, overflow := satmath.AddSat(value, delta, uint(bits))
+		return result, overflow != satmath.None
+	}
+
+	_, err := satmath.CheckedAdd(value, delta, uint(bits))
+	return satmath.AddWrap(value, delta, uint(bits)), err != nil
+}
+
+func checkSignedBitfieldOverflow(value, incr int64, bits uint64, ov Overflow) (int64, bool) {
+	if ov == Sat {
+		result, overflow := satmath.AddSat(value, incr, uint(bits))
+		return result, overflow != satmath.None
+	}
+
+	_, err := satmath.CheckedAdd(value, incr, uint(bits))
+	return satmath.AddWrap(value, incr, uint(bits)), err != nil
+}
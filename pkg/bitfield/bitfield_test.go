@@ -0,0 +1,86 @@
+package bitfield
+
+import "testing"
+
+func TestGetSetUnsignedAligned(t *testing.T) {
+	var data Bitmap
+	old := data.SetU(8, 8, 0x42)
+	if old != 0 {
+		t.Fatalf("got old %d, want 0", old)
+	}
+	if got := data.GetU(8, 8); got != 0x42 {
+		t.Fatalf("got %d, want 0x42", got)
+	}
+	if len(data) != 2 {
+		t.Fatalf("got len %d, want 2 (bitmap should grow to cover offset+bits)", len(data))
+	}
+}
+
+func TestGetSetUnsignedUnaligned(t *testing.T) {
+	var data Bitmap
+	data.SetU(3, 5, 0x1F)
+	if got := data.GetU(3, 5); got != 0x1F {
+		t.Fatalf("got %d, want 0x1F", got)
+	}
+	// Bits outside [3,8) must be untouched (still zero).
+	if got := data.GetU(0, 3); got != 0 {
+		t.Fatalf("bits before the field leaked: got %d, want 0", got)
+	}
+}
+
+func TestGetSignedSignExtends(t *testing.T) {
+	var data Bitmap
+	data.SetI(0, 8, -1)
+	if got := data.GetI(0, 8); got != -1 {
+		t.Fatalf("got %d, want -1", got)
+	}
+	data.SetI(0, 8, 127)
+	if got := data.GetI(0, 8); got != 127 {
+		t.Fatalf("got %d, want 127", got)
+	}
+}
+
+func TestGetBeyondBitmapReadsZero(t *testing.T) {
+	data := Bitmap{0xFF}
+	if got := data.GetU(100, 8); got != 0 {
+		t.Fatalf("got %d, want 0", got)
+	}
+}
+
+func TestIncrByWrap(t *testing.T) {
+	data := make(Bitmap, 1)
+	data.SetU(0, 8, 250)
+	got, err := data.IncrBy(0, 8, 10, false, Wrap)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 4 { // (250+10) mod 256
+		t.Fatalf("got %d, want 4", got)
+	}
+}
+
+func TestIncrBySat(t *testing.T) {
+	data := make(Bitmap, 1)
+	data.SetU(0, 8, 250)
+	got, err := data.IncrBy(0, 8, 10, false, Sat)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 255 {
+		t.Fatalf("got %d, want 255", got)
+	}
+}
+
+func TestIncrByFailReturnsErrAndLeavesBitmapUnchanged(t *testing.T) {
+	data := make(Bitmap, 1)
+	data.SetU(0, 8, 250)
+	before := append(Bitmap(nil), data...)
+
+	_, err := data.IncrBy(0, 8, 10, false, Fail)
+	if err != ErrOverflowFail {
+		t.Fatalf("got err %v, want ErrOverflowFail", err)
+	}
+	if string(data) != string(before) {
+		t.Fatalf("bitmap was modified despite OVERFLOW FAIL")
+	}
+}
@@ -0,0 +1,192 @@
+package bitfield
+
+import "encoding/binary"
+
+// getAligned reads bits (a multiple of 8, 8..64) starting at byteIndex
+// using a single wide load instead of iterating bit by bit. 24/40/48/56
+// bit reads load the next power-of-two size up and shift off the padding.
+func getAligned(data Bitmap, byteIndex, bits uint64) uint64 {
+	i := int(byteIndex)
+	switch bits {
+	case 8:
+		if i < len(data) {
+			return uint64(data[i])
+		}
+		return 0
+	case 16:
+		return readBE(data, i, 2)
+	case 24:
+		return readBE(data, i, 4) >> 8
+	case 32:
+		return readBE(data, i, 4)
+	case 40:
+		return readBE(data, i, 8) >> 24
+	case 48:
+		return readBE(data, i, 8) >> 16
+	case 56:
+		return readBE(data, i, 8) >> 8
+	case 64:
+		return readBE(data, i, 8)
+	default:
+		// Unreachable from getUnsignedBitfield (bits is always a multiple
+		// of 8 here), kept only as a safe fallback.
+		return getUnaligned(data, byteIndex*8, bits)
+	}
+}
+
+// setAligned writes bits (a multiple of 8, 8..64) starting at byteIndex
+// with a single wide store. The 24/40/48/56 bit cases shift the value into
+// the top of the next power-of-two size and merge back in the low padding
+// bytes that the wider store would otherwise clobber.
+func setAligned(data Bitmap, byteIndex, bits, value uint64) Bitmap {
+	i := int(byteIndex)
+	switch bits {
+	case 8:
+		data[i] = byte(value)
+	case 16:
+		writeBE(data, i, 2, value)
+	case 32:
+		writeBE(data, i, 4, value)
+	case 64:
+		writeBE(data, i, 8, value)
+	case 24:
+		pad := readBE(data, i, 4) & 0xFF
+		writeBE(data, i, 4, value<<8|pad)
+	case 40:
+		pad := readBE(data, i, 8) & 0xFFFFFF
+		writeBE(data, i, 8, value<<24|pad)
+	case 48:
+		pad := readBE(data, i, 8) & 0xFFFF
+		writeBE(data, i, 8, value<<16|pad)
+	case 56:
+		pad := readBE(data, i, 8) & 0xFF
+		writeBE(data, i, 8, value<<8|pad)
+	default:
+		return setUnaligned(data, byteIndex*8, bits, value)
+	}
+	return data
+}
+
+// readBE reads n (2, 4, or 8) big-endian bytes starting at byteIndex,
+// treating any positions beyond the end of data as zero.
+func readBE(data Bitmap, byteIndex, n int) uint64 {
+	var buf [8]byte
+	for i := 0; i < n; i++ {
+		if idx := byteIndex + i; idx >= 0 && idx < len(data) {
+			buf[i] = data[idx]
+		}
+	}
+	switch n {
+	case 2:
+		return uint64(binary.BigEndian.Uint16(buf[:2]))
+	case 4:
+		return uint64(binary.BigEndian.Uint32(buf[:4]))
+	default:
+		return binary.BigEndian.Uint64(buf[:8])
+	}
+}
+
+// writeBE writes the low n (2, 4, or 8) bytes of value, big-endian, at
+// byteIndex, skipping any positions beyond the end of data (callers that
+// pad a narrower field out to a wider store, e.g. 24 bits via a 32-bit
+// store, may address a trailing byte the bitmap was never grown to hold).
+func writeBE(data Bitmap, byteIndex, n int, value uint64) {
+	var buf [8]byte
+	switch n {
+	case 2:
+		binary.BigEndian.PutUint16(buf[:2], uint16(value))
+	case 4:
+		binary.BigEndian.PutUint32(buf[:4], uint32(value))
+	default:
+		binary.BigEndian.PutUint64(buf[:8], value)
+	}
+	for i := 0; i < n; i++ {
+		if idx := byteIndex + i; idx >= 0 && idx < len(data) {
+			data[idx] = buf[i]
+		}
+	}
+}
+
+// maskBits returns a mask with the low bits bits set.
+func maskBits(bits uint64) uint64 {
+	if bits >= 64 {
+		return ^uint64(0)
+	}
+	return (uint64(1) << bits) - 1
+}
+
+// shiftLeft128 returns the 128-bit result of v<<s as (hi, lo), for
+// s in [0, 128). v itself is never wider than 64 bits.
+func shiftLeft128(v uint64, s uint) (hi, lo uint64) {
+	switch {
+	case s == 0:
+		return 0, v
+	case s >= 128:
+		return 0, 0
+	case s < 64:
+		return v >> (64 - s), v << s
+	default:
+		return v << (s - 64), 0
+	}
+}
+
+// shiftRight128 returns the low 64 bits of the 128-bit value (hi:lo)>>s,
+// for s in [0, 128).
+func shiftRight128(hi, lo uint64, s uint) uint64 {
+	switch {
+	case s == 0:
+		return lo
+	case s >= 128:
+		return 0
+	case s < 64:
+		return (hi << (64 - s)) | (lo >> s)
+	default:
+		return hi >> (s - 64)
+	}
+}
+
+// getUnaligned reads bits (1..64) starting at an arbitrary bit offset. It
+// loads the two adjacent 64-bit words spanning the range, then shifts and
+// masks once instead of touching every bit.
+func getUnaligned(data Bitmap, offset, bits uint64) uint64 {
+	byteIndex := int(offset >> 3)
+	bitOffset := offset & 0x7
+
+	hi := readBE(data, byteIndex, 8)
+	lo := readBE(data, byteIndex+8, 8)
+
+	s := uint(128 - bitOffset - bits)
+	return shiftRight128(hi, lo, s) & maskBits(bits)
+}
+
+// setUnaligned writes bits (1..64) of value starting at an arbitrary bit
+// offset. Like getUnaligned, it works over the two adjacent 64-bit words
+// spanning the range with a single shift-and-mask, then writes back only
+// the bytes that were actually touched.
+func setUnaligned(data Bitmap, offset, bits, value uint64) Bitmap {
+	byteIndex := int(offset >> 3)
+	bitOffset := offset & 0x7
+	s := uint(128 - bitOffset - bits)
+
+	hi := readBE(data, byteIndex, 8)
+	lo := readBE(data, byteIndex+8, 8)
+
+	maskHi, maskLo := shiftLeft128(maskBits(bits), s)
+	valHi, valLo := shiftLeft128(value&maskBits(bits), s)
+	newHi := (hi &^ maskHi) | valHi
+	newLo := (lo &^ maskLo) | valLo
+
+	nBytes := int((bitOffset + bits + 7) / 8)
+	for j := 0; j < nBytes; j++ {
+		idx := byteIndex + j
+		if idx >= len(data) {
+			break
+		}
+		if j < 8 {
+			data[idx] = byte(newHi >> uint(8*(7-j)))
+		} else {
+			data[idx] = byte(newLo >> uint(8*(7-(j-8))))
+		}
+	}
+	return data
+}
@@ -0,0 +1,90 @@
+package bitfield
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BitField describes one contiguous window of bits within a Bitmap.
+type BitField struct {
+	Offset uint8
+	Width  uint8
+}
+
+// BitFields is an ordered list of BitField windows that are concatenated
+// MSB-first into a single logical value. This lets a caller address a
+// value whose bits are scattered across several non-adjacent windows in
+// the byte slice, the same abstraction disassemblers use for opcodes whose
+// immediate value is split across the instruction word.
+type BitFields []BitField
+
+// TotalBits returns the combined width of all ranges.
+func (f BitFields) TotalBits() uint {
+	var total uint
+	for _, bf := range f {
+		total += uint(bf.Width)
+	}
+	return total
+}
+
+// GetMulti concatenates the bit ranges in fields, MSB-first, into a single
+// value, sign-extending the result if signed is set.
+func GetMulti(data Bitmap, fields BitFields, signed bool) int64 {
+	var unsigned uint64
+	for _, bf := range fields {
+		unsigned = (unsigned << bf.Width) | getUnsignedBitfield(data, uint64(bf.Offset), uint64(bf.Width))
+	}
+	if signed {
+		return signExtend(unsigned, uint64(fields.TotalBits()))
+	}
+	return int64(unsigned)
+}
+
+// SetMulti writes value across the bit ranges in fields, MSB-first, growing
+// data as needed, and returns the value that was previously stored there.
+func SetMulti(data *Bitmap, fields BitFields, signed bool, value int64) int64 {
+	old := GetMulti(*data, fields, signed)
+
+	unsigned := uint64(value)
+	for i := len(fields) - 1; i >= 0; i-- {
+		bf := fields[i]
+		chunk := unsigned & (0xFFFFFFFFFFFFFFFF >> (64 - uint64(bf.Width)))
+		*data = setUnsignedBitfield(*data, uint64(bf.Offset), uint64(bf.Width), chunk)
+		unsigned >>= bf.Width
+	}
+	return old
+}
+
+// parseRanges parses a comma-separated list of inclusive "start..end" bit
+// ranges, as used by the "@" suffix of a type specifier.
+func parseRanges(spec string) (BitFields, error) {
+	parts := strings.Split(spec, ",")
+	fields := make(BitFields, 0, len(parts))
+
+	for _, part := range parts {
+		start, end, ok := strings.Cut(part, "..")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q, want start..end", ErrBadRange, part)
+		}
+
+		startBit, err := strconv.ParseUint(start, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrBadRange, part, err)
+		}
+		endBit, err := strconv.ParseUint(end, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrBadRange, part, err)
+		}
+		if endBit < startBit {
+			return nil, fmt.Errorf("%w: %q: end before start", ErrBadRange, part)
+		}
+
+		fields = append(fields, BitField{
+			Offset: uint8(startBit),
+			Width:  uint8(endBit-startBit) + 1,
+		})
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,61 @@
+package bitfield
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseRangesValid(t *testing.T) {
+	fields, err := parseRanges("0..3,7..9")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := BitFields{{Offset: 0, Width: 4}, {Offset: 7, Width: 3}}
+	if len(fields) != len(want) || fields[0] != want[0] || fields[1] != want[1] {
+		t.Fatalf("got %+v, want %+v", fields, want)
+	}
+	if got := fields.TotalBits(); got != 7 {
+		t.Fatalf("got TotalBits %d, want 7", got)
+	}
+}
+
+func TestParseRangesRejectsMissingSeparator(t *testing.T) {
+	if _, err := parseRanges("0-3"); !errors.Is(err, ErrBadRange) {
+		t.Fatalf("got err %v, want ErrBadRange", err)
+	}
+}
+
+func TestParseRangesRejectsNonNumeric(t *testing.T) {
+	if _, err := parseRanges("a..3"); !errors.Is(err, ErrBadRange) {
+		t.Fatalf("got err %v, want ErrBadRange", err)
+	}
+}
+
+func TestParseRangesRejectsEndBeforeStart(t *testing.T) {
+	if _, err := parseRanges("9..3"); !errors.Is(err, ErrBadRange) {
+		t.Fatalf("got err %v, want ErrBadRange", err)
+	}
+}
+
+func TestSetMultiRoundTrips(t *testing.T) {
+	fields := BitFields{{Offset: 0, Width: 4}, {Offset: 7, Width: 3}}
+	var data Bitmap
+
+	old := SetMulti(&data, fields, false, 89)
+	if old != 0 {
+		t.Fatalf("got old %d, want 0", old)
+	}
+	if got := GetMulti(data, fields, false); got != 89 {
+		t.Fatalf("got %d, want 89", got)
+	}
+}
+
+func TestGetMultiSignExtends(t *testing.T) {
+	fields := BitFields{{Offset: 0, Width: 4}, {Offset: 4, Width: 4}}
+	var data Bitmap
+	SetMulti(&data, fields, true, -1)
+
+	if got := GetMulti(data, fields, true); got != -1 {
+		t.Fatalf("got %d, want -1", got)
+	}
+}
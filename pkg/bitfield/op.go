@@ -0,0 +1,152 @@
+package bitfield
+
+import (
+	"strings"
+)
+
+// OpKind identifies which BITFIELD subcommand an Op represents.
+type OpKind int
+
+const (
+	OpGet OpKind = iota
+	OpSet
+	OpIncrBy
+)
+
+// Op is one parsed BITFIELD subcommand, ready to run against a Bitmap with
+// Apply.
+type Op struct {
+	Kind     OpKind
+	Type     Type
+	Offset   uint64
+	Value    int64 // the SET value, or the INCRBY increment
+	Overflow Overflow
+}
+
+// Parse parses the subcommand tokens following "BITFIELD <key>" (or
+// "BITFIELD_RO <key>") into a sequence of Ops. An OVERFLOW clause is
+// resolved into the Overflow carried by the next INCRBY Op, rather than
+// becoming an Op of its own, and reverts to Wrap after that INCRBY. GET and
+// SET Ops always carry Wrap and leave a pending OVERFLOW mode in place.
+func Parse(argv []string) ([]Op, error) {
+	var ops []Op
+	overflow := Wrap
+
+	for i := 0; i < len(argv); {
+		switch strings.ToLower(argv[i]) {
+		case "get":
+			if i+2 >= len(argv) {
+				return nil, ErrSyntax
+			}
+			typ, err := ParseType(argv[i+1])
+			if err != nil {
+				return nil, err
+			}
+			offset, err := ParseOffset(argv[i+2], typ.Bits)
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, Op{Kind: OpGet, Type: typ, Offset: offset})
+			i += 3
+
+		case "set":
+			if i+3 >= len(argv) {
+				return nil, ErrSyntax
+			}
+			typ, err := ParseType(argv[i+1])
+			if err != nil {
+				return nil, err
+			}
+			offset, err := ParseOffset(argv[i+2], typ.Bits)
+			if err != nil {
+				return nil, err
+			}
+			value, err := ParseValue(argv[i+3])
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, Op{Kind: OpSet, Type: typ, Offset: offset, Value: int64(value)})
+			i += 4
+
+		case "incrby":
+			if i+3 >= len(argv) {
+				return nil, ErrSyntax
+			}
+			typ, err := ParseType(argv[i+1])
+			if err != nil {
+				return nil, err
+			}
+			offset, err := ParseOffset(argv[i+2], typ.Bits)
+			if err != nil {
+				return nil, err
+			}
+			incr, err := ParseValue(argv[i+3])
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, Op{Kind: OpIncrBy, Type: typ, Offset: offset, Value: int64(incr), Overflow: overflow})
+			overflow = Wrap
+			i += 4
+
+		case "overflow":
+			if i+1 >= len(argv) {
+				return nil, ErrSyntax
+			}
+			ov, err := ParseOverflow(argv[i+1])
+			if err != nil {
+				return nil, err
+			}
+			overflow = ov
+			i += 2
+
+		default:
+			return nil, ErrSyntax
+		}
+	}
+
+	return ops, nil
+}
+
+// Apply runs op against data, growing it if necessary, and returns the
+// resulting value. The second result is false when OVERFLOW FAIL
+// suppressed an INCRBY, or when op is an INCRBY on a multi-range type; in
+// both cases data is left unmodified and the caller should report a nil
+// reply for this Op instead of 0.
+func Apply(data *Bitmap, op Op) (int64, bool) {
+	if op.Type.Ranges != nil {
+		switch op.Kind {
+		case OpGet:
+			return GetMulti(*data, op.Type.Ranges, op.Type.Signed), true
+		case OpSet:
+			return SetMulti(data, op.Type.Ranges, op.Type.Signed, op.Value), true
+		default:
+			// INCRBY over a multi-range type has no defined semantics here
+			// (there is no single contiguous field to add to).
+			return 0, false
+		}
+	}
+
+	switch op.Kind {
+	case OpGet:
+		if op.Type.Signed {
+			return data.GetI(uint(op.Offset), op.Type.Bits), true
+		}
+		return int64(data.GetU(uint(op.Offset), op.Type.Bits)), true
+
+	case OpSet:
+		if op.Type.Signed {
+			return data.SetI(uint(op.Offset), op.Type.Bits, op.Value), true
+		}
+		return int64(data.SetU(uint(op.Offset), op.Type.Bits, uint64(op.Value))), true
+
+	case OpIncrBy:
+		r, err := data.IncrBy(uint(op.Offset), op.Type.Bits, op.Value, op.Type.Signed, op.Overflow)
+		if err != nil {
+			return 0, false
+		}
+		return r, true
+
+	default:
+		return 0, false
+	}
+}
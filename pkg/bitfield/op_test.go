@@ -0,0 +1,76 @@
+package bitfield
+
+import "testing"
+
+func TestParseApplyGetSet(t *testing.T) {
+	ops, err := Parse([]string{"SET", "u8", "8", "66", "GET", "u8", "8"})
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("got %d ops, want 2", len(ops))
+	}
+
+	var data Bitmap
+	if _, ok := Apply(&data, ops[0]); !ok {
+		t.Fatalf("SET op reported failure")
+	}
+	got, ok := Apply(&data, ops[1])
+	if !ok {
+		t.Fatalf("GET op reported failure")
+	}
+	if got != 66 {
+		t.Fatalf("got %d, want 66", got)
+	}
+}
+
+func TestParseApplyIncrByOverflowFail(t *testing.T) {
+	ops, err := Parse([]string{"SET", "u8", "0", "250", "OVERFLOW", "FAIL", "INCRBY", "u8", "0", "10"})
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	var data Bitmap
+	Apply(&data, ops[0])
+	if _, ok := Apply(&data, ops[1]); ok {
+		t.Fatalf("INCRBY reported success despite OVERFLOW FAIL")
+	}
+}
+
+// TestApplyRangedTypeConcatenates matches the reviewer's repro: a GET on a
+// multi-range type must return the concatenated value, not a flat read
+// starting at the op's (meaningless, for ranges) Offset.
+func TestApplyRangedTypeConcatenates(t *testing.T) {
+	data := Bitmap{0xB0, 0x40}
+	typ := Type{Bits: 7, Signed: false, Ranges: BitFields{
+		{Offset: 0, Width: 4},
+		{Offset: 7, Width: 3},
+	}}
+
+	got, ok := Apply(&data, Op{Kind: OpGet, Type: typ})
+	if !ok {
+		t.Fatalf("GET reported failure")
+	}
+	if want := GetMulti(data, typ.Ranges, false); got != want {
+		t.Fatalf("got %d, want %d (from GetMulti directly)", got, want)
+	}
+	if got != 89 {
+		t.Fatalf("got %d, want 89", got)
+	}
+}
+
+func TestParseTypeRangesWidthMismatchRejected(t *testing.T) {
+	if _, err := ParseType("u6@0..3,7..9"); err == nil {
+		t.Fatalf("expected error: declared width 6 does not match ranges total 7")
+	}
+}
+
+func TestParseTypeRangesWidthMatch(t *testing.T) {
+	typ, err := ParseType("u7@0..3,7..9")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if typ.Bits != 7 || len(typ.Ranges) != 2 {
+		t.Fatalf("got %+v, want Bits=7 with 2 ranges", typ)
+	}
+}
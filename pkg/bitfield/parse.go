@@ -0,0 +1,126 @@
+package bitfield
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Type describes a BITFIELD type specifier such as "u8" or "i16": a bit
+// width together with its signedness. Ranges is non-nil when the spec used
+// the "@offset..offset,..." suffix to address a value scattered across
+// several non-adjacent windows instead of one contiguous field.
+type Type struct {
+	Bits   uint
+	Signed bool
+	Ranges BitFields
+}
+
+// ParseType parses a type specifier of the form [u|i][1-64], optionally
+// followed by "@<range>,<range>,..." where each range is "start..end"
+// (inclusive) naming the bit windows to concatenate MSB-first, e.g.
+// "u7@0..3,7..9". Unsigned fields support up to 63 bits and signed fields
+// up to 64 bits, matching Redis (u64 would not fit in the int64 reply, so
+// it is rejected).
+func ParseType(spec string) (Type, error) {
+	base, rangesSpec, hasRanges := strings.Cut(spec, "@")
+	if len(base) != 2 && len(base) != 3 {
+		return Type{}, ErrInvalidType
+	}
+
+	var signed bool
+	switch base[0] {
+	case 'u':
+		signed = false
+	case 'i':
+		signed = true
+	default:
+		return Type{}, ErrInvalidType
+	}
+
+	bits, err := strconv.ParseUint(base[1:], 10, 64)
+	if err != nil {
+		return Type{}, ErrInvalidType
+	}
+
+	if signed && bits > 64 {
+		return Type{}, ErrInvalidType
+	}
+	if !signed && bits > 63 {
+		return Type{}, ErrInvalidType
+	}
+
+	if !hasRanges {
+		return Type{Bits: uint(bits), Signed: signed}, nil
+	}
+
+	ranges, err := parseRanges(rangesSpec)
+	if err != nil {
+		return Type{}, err
+	}
+
+	// The declared width (the "7" in "u7@0..3,7..9") already passed the
+	// 63/64-bit ceiling check above, so requiring it to match the ranges'
+	// total enforces that ceiling here too.
+	total := ranges.TotalBits()
+	if total != uint(bits) {
+		return Type{}, fmt.Errorf("%w: ranges total %d bits, declared width is %d", ErrInvalidType, total, bits)
+	}
+
+	return Type{Bits: total, Signed: signed, Ranges: ranges}, nil
+}
+
+// ParseOffset parses a BITFIELD offset, which is either a plain bit offset
+// ("100") or a type-relative offset ("#100", meaning the 100th value of the
+// given bit width).
+func ParseOffset(spec string, bits uint) (uint64, error) {
+	if spec == "" {
+		return 0, ErrBadOffset
+	}
+
+	if spec[0] == '#' {
+		n, err := strconv.ParseUint(spec[1:], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrBadOffset, spec)
+		}
+		return n * uint64(bits), nil
+	}
+
+	offset, err := strconv.ParseUint(spec, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrBadOffset, spec)
+	}
+	return offset, nil
+}
+
+// ParseValue parses a SET/INCRBY argument, which may be a negative integer
+// stored as its uint64 two's-complement bit pattern.
+func ParseValue(spec string) (uint64, error) {
+	if strings.HasPrefix(spec, "-") {
+		n, err := strconv.ParseInt(spec, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrBadValue, spec)
+		}
+		return uint64(n), nil
+	}
+
+	n, err := strconv.ParseUint(spec, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrBadValue, spec)
+	}
+	return n, nil
+}
+
+// ParseOverflow parses the argument to an OVERFLOW clause.
+func ParseOverflow(spec string) (Overflow, error) {
+	switch strings.ToLower(spec) {
+	case "wrap":
+		return Wrap, nil
+	case "sat":
+		return Sat, nil
+	case "fail":
+		return Fail, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrBadOverflowType, spec)
+	}
+}
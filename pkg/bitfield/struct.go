@@ -0,0 +1,274 @@
+package bitfield
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ByteOrder selects which end of each byte bit 0 of a field starts at when
+// packing/unpacking struct fields.
+type ByteOrder int
+
+const (
+	// MSBFirst lays bits out starting at the most significant bit of the
+	// first byte, matching the BITFIELD behavior used elsewhere in this
+	// package.
+	MSBFirst ByteOrder = iota
+	// LSBFirst lays bits out starting at the least significant bit of the
+	// first byte.
+	LSBFirst
+)
+
+// Config controls how Pack/Unpack lay out tagged struct fields.
+type Config struct {
+	ByteOrder ByteOrder
+	// MaxBits caps the total width of the packed struct; zero means
+	// unlimited.
+	MaxBits uint
+}
+
+// DefaultConfig matches the bit ordering BITFIELD itself uses: MSB-first,
+// no width cap.
+var DefaultConfig = Config{ByteOrder: MSBFirst}
+
+type structField struct {
+	index  int
+	name   string
+	bits   uint
+	signed bool
+	offset uint
+}
+
+// layoutFields walks the exported `bitfield:"name,bits"` tags of rt in
+// declaration order and assigns each one a contiguous bit offset.
+func layoutFields(rt reflect.Type, cfg Config) ([]structField, uint, error) {
+	var fields []structField
+	var offset uint
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup("bitfield")
+		if !ok {
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			return nil, 0, fmt.Errorf("bitfield: field %s is unexported, cannot be packed", sf.Name)
+		}
+
+		name, bits, err := parseFieldTag(tag, sf.Name)
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := validateFieldWidth(sf.Type.Kind(), bits); err != nil {
+			return nil, 0, fmt.Errorf("bitfield: field %s: %w", sf.Name, err)
+		}
+
+		fields = append(fields, structField{
+			index:  i,
+			name:   name,
+			bits:   bits,
+			signed: isSignedKind(sf.Type.Kind()),
+			offset: offset,
+		})
+		offset += bits
+	}
+
+	if cfg.MaxBits > 0 && offset > cfg.MaxBits {
+		return nil, 0, fmt.Errorf("bitfield: struct needs %d bits, exceeds MaxBits %d", offset, cfg.MaxBits)
+	}
+	return fields, offset, nil
+}
+
+func parseFieldTag(tag, fieldName string) (string, uint, error) {
+	parts := strings.SplitN(tag, ",", 2)
+	name := fieldName
+	if parts[0] != "" {
+		name = parts[0]
+	}
+	if len(parts) < 2 || parts[1] == "" {
+		return "", 0, fmt.Errorf("bitfield: tag %q on %s is missing a bit width", tag, fieldName)
+	}
+
+	bits, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("bitfield: invalid bit width in tag %q on %s", tag, fieldName)
+	}
+	return name, uint(bits), nil
+}
+
+func isSignedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateFieldWidth rejects bit widths that cannot round-trip through the
+// field's declared Go type, e.g. a 12-bit field on a uint8.
+func validateFieldWidth(k reflect.Kind, bits uint) error {
+	var max uint
+	switch k {
+	case reflect.Bool:
+		max = 1
+	case reflect.Uint8, reflect.Int8:
+		max = 8
+	case reflect.Uint16, reflect.Int16:
+		max = 16
+	case reflect.Uint32, reflect.Int32:
+		max = 32
+	case reflect.Uint64, reflect.Uint:
+		max = 63
+	case reflect.Int64, reflect.Int:
+		max = 64
+	default:
+		return fmt.Errorf("%w: unsupported field kind %s", ErrInvalidType, k)
+	}
+	if bits == 0 || bits > max {
+		return fmt.Errorf("%w: %d bits does not fit in %s (max %d)", ErrInvalidType, bits, k, max)
+	}
+	return nil
+}
+
+// Pack lays the tagged fields of the struct v (or a pointer to one) out
+// into a contiguous bit-packed byte slice, using DefaultConfig.
+func Pack(v any) ([]byte, error) {
+	return PackConfig(DefaultConfig, v)
+}
+
+// PackConfig is Pack with an explicit Config.
+func PackConfig(cfg Config, v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("bitfield: Pack requires a struct or pointer to struct, got %s", rv.Kind())
+	}
+
+	fields, _, err := layoutFields(rv.Type(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var bm Bitmap
+	for _, f := range fields {
+		fv := rv.Field(f.index)
+
+		var value uint64
+		switch {
+		case f.signed:
+			value = uint64(fv.Int())
+		case fv.Kind() == reflect.Bool:
+			if fv.Bool() {
+				value = 1
+			}
+		default:
+			value = fv.Uint()
+		}
+
+		setBits(&bm, cfg.ByteOrder, f.offset, f.bits, value)
+	}
+	return bm, nil
+}
+
+// Unpack is the inverse of Pack: it reads a packed byte slice laid out with
+// the same tags back into the struct pointed to by v, using DefaultConfig.
+func Unpack(data []byte, v any) error {
+	return UnpackConfig(DefaultConfig, data, v)
+}
+
+// UnpackConfig is Unpack with an explicit Config.
+func UnpackConfig(cfg Config, data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bitfield: Unpack requires a pointer to struct, got %s", rv.Kind())
+	}
+	rv = rv.Elem()
+
+	fields, _, err := layoutFields(rv.Type(), cfg)
+	if err != nil {
+		return err
+	}
+
+	bm := Bitmap(data)
+	for _, f := range fields {
+		fv := rv.Field(f.index)
+		unsigned := getBits(bm, cfg.ByteOrder, f.offset, f.bits)
+
+		switch {
+		case f.signed:
+			fv.SetInt(signExtend(unsigned, uint64(f.bits)))
+		case fv.Kind() == reflect.Bool:
+			fv.SetBool(unsigned != 0)
+		default:
+			fv.SetUint(unsigned)
+		}
+	}
+	return nil
+}
+
+func setBits(bm *Bitmap, order ByteOrder, offset, bits uint, value uint64) {
+	if order == LSBFirst {
+		*bm = setUnsignedBitfieldLSB(*bm, uint64(offset), uint64(bits), value)
+		return
+	}
+	*bm = setUnsignedBitfield(*bm, uint64(offset), uint64(bits), value)
+}
+
+func getBits(bm Bitmap, order ByteOrder, offset, bits uint) uint64 {
+	if order == LSBFirst {
+		return getUnsignedBitfieldLSB(bm, uint64(offset), uint64(bits))
+	}
+	return getUnsignedBitfield(bm, uint64(offset), uint64(bits))
+}
+
+// getUnsignedBitfieldLSB and setUnsignedBitfieldLSB mirror
+// getUnsignedBitfield/setUnsignedBitfield but number bits starting at the
+// least significant bit of each byte instead of the most significant.
+func getUnsignedBitfieldLSB(data Bitmap, offset, bits uint64) uint64 {
+	var value uint64
+	for i := uint64(0); i < bits; i++ {
+		byteIndex := offset >> 3
+		bit := offset & 0x7
+		if byteIndex < uint64(len(data)) {
+			byteval := data[byteIndex]
+			bitval := (byteval >> bit) & 1
+			value |= uint64(bitval) << i
+		}
+		offset++
+	}
+	return value
+}
+
+func setUnsignedBitfieldLSB(data Bitmap, offset, bits, value uint64) Bitmap {
+	if bits < 64 {
+		value &= 0xFFFFFFFFFFFFFFFF >> (64 - bits)
+	}
+
+	if offset+bits > uint64(len(data)*8) {
+		numExpandBits := offset + bits - uint64(len(data)*8)
+		numExpandBytes := numExpandBits / 8
+		if numExpandBits%8 > 0 {
+			numExpandBytes++
+		}
+		for i := uint64(0); i < numExpandBytes; i++ {
+			data = append(data, 0x00)
+		}
+	}
+
+	for i := uint64(0); i < bits; i++ {
+		bitval := byte((value >> i) & 1)
+		byteIndex := offset >> 3
+		bit := offset & 0x7
+		byteval := data[byteIndex]
+		byteval &= ^(1 << bit)
+		byteval |= bitval << bit
+		data[byteIndex] = byteval
+		offset++
+	}
+	return data
+}
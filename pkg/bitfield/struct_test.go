@@ -0,0 +1,73 @@
+package bitfield
+
+import "testing"
+
+type packedHeader struct {
+	Version uint8  `bitfield:"version,4"`
+	Flags   uint8  `bitfield:"flags,4"`
+	Length  uint16 `bitfield:"length,12"`
+	Signed  int8   `bitfield:"signed,8"`
+}
+
+func TestPackUnpackRoundTripMSBFirst(t *testing.T) {
+	in := packedHeader{Version: 5, Flags: 9, Length: 1234, Signed: -7}
+
+	data, err := Pack(in)
+	if err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+
+	var out packedHeader
+	if err := Unpack(data, &out); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+	if out != in {
+		t.Fatalf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestPackUnpackRoundTripLSBFirst(t *testing.T) {
+	cfg := Config{ByteOrder: LSBFirst}
+	in := packedHeader{Version: 3, Flags: 1, Length: 42, Signed: 100}
+
+	data, err := PackConfig(cfg, in)
+	if err != nil {
+		t.Fatalf("PackConfig: %v", err)
+	}
+
+	var out packedHeader
+	if err := UnpackConfig(cfg, data, &out); err != nil {
+		t.Fatalf("UnpackConfig: %v", err)
+	}
+	if out != in {
+		t.Fatalf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestUnpackLSBFirstEmptyBufferDoesNotPanic(t *testing.T) {
+	var out packedHeader
+	if err := UnpackConfig(Config{ByteOrder: LSBFirst}, []byte{}, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != (packedHeader{}) {
+		t.Fatalf("got %+v, want zero value", out)
+	}
+}
+
+func TestLayoutFieldsRejectsUnexportedField(t *testing.T) {
+	type bad struct {
+		hidden uint8 `bitfield:"hidden,4"`
+	}
+	if err := Unpack([]byte{0}, &bad{}); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+}
+
+func TestLayoutFieldsRejectsOverwideField(t *testing.T) {
+	type bad struct {
+		X uint8 `bitfield:"x,12"`
+	}
+	if err := Unpack([]byte{0, 0}, &bad{}); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+}
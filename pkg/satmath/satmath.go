@@ -0,0 +1,138 @@
+// Package satmath implements generic N-bit saturating and wrapping integer
+// addition. It started as the overflow-handling core of BITFIELD INCRBY,
+// but the same range checks are useful anywhere a value has to be coerced
+// into a narrower bit width, such as protobuf varint range checks or codec
+// numeric coercion.
+package satmath
+
+import (
+	"errors"
+	"math"
+)
+
+// Integer is the set of built-in integer types (and named types derived
+// from them) the helpers in this package operate on.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// Overflow reports which direction, if any, an addition overflowed in.
+type Overflow int
+
+const (
+	None Overflow = iota
+	Overflowed
+	Underflowed
+)
+
+// ErrOverflow is returned by CheckedAdd when a+b does not fit in bits.
+var ErrOverflow = errors.New("satmath: addition overflows the given bit width")
+
+// isSigned reports whether T is a signed integer type: for unsigned T,
+// 0-1 wraps around to the (positive) max value instead of going negative.
+func isSigned[T Integer]() bool {
+	return T(0)-T(1) < T(0)
+}
+
+// AddWrap adds a and b and truncates the result to its low bits bits,
+// matching BITFIELD's OVERFLOW WRAP: the result is a+b reduced modulo
+// 2^bits, sign-extended back out for signed T. For unsigned T, b may
+// itself be the two's-complement encoding of a negative delta (e.g.
+// uint64(int64(-5))), the same way Go's own unsigned arithmetic expresses
+// subtraction.
+func AddWrap[T Integer](a, b T, bits uint) T {
+	if isSigned[T]() {
+		return T(wrapSigned(int64(a), int64(b), bits))
+	}
+	return T(wrapUnsigned(uint64(a), uint64(b), bits))
+}
+
+// AddSat adds a and b and clamps the result to the representable range of
+// bits, reporting whether (and in which direction) it had to clamp.
+func AddSat[T Integer](a, b T, bits uint) (T, Overflow) {
+	if isSigned[T]() {
+		v, ov := addSatSigned(int64(a), int64(b), bits)
+		return T(v), ov
+	}
+	v, ov := addSatUnsigned(uint64(a), uint64(b), bits)
+	return T(v), ov
+}
+
+// CheckedAdd adds a and b, returning ErrOverflow instead of a result when
+// the sum does not fit in bits, matching BITFIELD's OVERFLOW FAIL.
+func CheckedAdd[T Integer](a, b T, bits uint) (T, error) {
+	result, ov := AddSat(a, b, bits)
+	if ov != None {
+		var zero T
+		return zero, ErrOverflow
+	}
+	return result, nil
+}
+
+func maxUnsigned(bits uint) uint64 {
+	if bits >= 64 {
+		return math.MaxUint64
+	}
+	return (uint64(1) << bits) - 1
+}
+
+func wrapUnsigned(value, delta uint64, bits uint) uint64 {
+	sum := value + delta
+	if bits >= 64 {
+		return sum
+	}
+	return sum & ((uint64(1) << bits) - 1)
+}
+
+func addSatUnsigned(value, delta uint64, bits uint) (uint64, Overflow) {
+	max := maxUnsigned(bits)
+	incr := int64(delta)
+
+	maxincr := max - value
+
+	if value > max || (incr > 0 && uint64(incr) > maxincr) {
+		return max, Overflowed
+	}
+	if incr < 0 && uint64(-incr) > value {
+		return 0, Underflowed
+	}
+	return value + delta, None
+}
+
+func wrapSigned(value, incr int64, bits uint) int64 {
+	if bits >= 64 {
+		return value + incr
+	}
+	msb := uint64(1) << (bits - 1)
+	mask := uint64(0xFFFFFFFFFFFFFFFF) << (bits - 1)
+	c := uint64(value) + uint64(incr)
+	if c&msb > 0 {
+		c |= mask
+	} else {
+		c &= ^mask
+	}
+	return int64(c)
+}
+
+func addSatSigned(value, incr int64, bits uint) (int64, Overflow) {
+	var max, min int64
+	if bits >= 64 {
+		max = math.MaxInt64
+		min = math.MinInt64
+	} else {
+		max = int64(1<<(bits-1)) - 1
+		min = -max - 1
+	}
+
+	maxincr := max - value
+	minincr := min - value
+
+	if value > max || (bits < 64 && incr > maxincr) || (value >= 0 && incr > 0 && incr > maxincr) {
+		return max, Overflowed
+	}
+	if value < min || (bits < 64 && incr < minincr) || (value < 0 && incr < 0 && incr < minincr) {
+		return min, Underflowed
+	}
+	return value + incr, None
+}
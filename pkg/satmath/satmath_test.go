@@ -0,0 +1,77 @@
+package satmath
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestAddWrapUnsignedMatchesReference compares AddWrap against the
+// reference formula BITFIELD itself uses for OVERFLOW WRAP on unsigned
+// fields, across randomized (bits, value, incr) triples.
+func TestAddWrapUnsignedMatchesReference(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 10000; i++ {
+		bits := uint(1 + r.Intn(63)) // unsigned BITFIELD types go up to u63
+		mask := (uint64(1) << bits) - 1
+		value := r.Uint64() & mask
+
+		incr := r.Int63()
+		if r.Intn(2) == 0 {
+			incr = -incr
+		}
+
+		want := uint64(int64(value)+incr) & mask
+		got := AddWrap(value, uint64(incr), bits)
+
+		if got != want {
+			t.Fatalf("bits=%d value=%d incr=%d: got %d, want %d", bits, value, incr, got, want)
+		}
+	}
+}
+
+// TestAddWrapSignedMatchesReference does the same comparison for signed
+// fields, where both operands are already int64.
+func TestAddWrapSignedMatchesReference(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 10000; i++ {
+		bits := uint(1 + r.Intn(64)) // signed BITFIELD types go up to i64
+		shift := 64 - bits
+		value := int64(r.Uint64()<<shift) >> shift
+
+		incr := r.Int63()
+		if r.Intn(2) == 0 {
+			incr = -incr
+		}
+
+		want := int64(uint64(int64(value)+incr)<<shift) >> shift
+		got := AddWrap(value, incr, bits)
+
+		if got != want {
+			t.Fatalf("bits=%d value=%d incr=%d: got %d, want %d", bits, value, incr, got, want)
+		}
+	}
+}
+
+func TestAddSatUnsignedClamps(t *testing.T) {
+	got, ov := AddSat[uint64](250, 10, 8)
+	if ov != Overflowed || got != 255 {
+		t.Fatalf("got (%d, %v), want (255, Overflowed)", got, ov)
+	}
+
+	neg := int64(-10)
+	got, ov = AddSat(uint64(3), uint64(neg), 8)
+	if ov != Underflowed || got != 0 {
+		t.Fatalf("got (%d, %v), want (0, Underflowed)", got, ov)
+	}
+}
+
+func TestCheckedAddReportsOverflow(t *testing.T) {
+	if _, err := CheckedAdd[uint64](250, 10, 8); err != ErrOverflow {
+		t.Fatalf("got err %v, want ErrOverflow", err)
+	}
+	if v, err := CheckedAdd[uint64](250, 5, 8); err != nil || v != 255 {
+		t.Fatalf("got (%d, %v), want (255, nil)", v, err)
+	}
+}